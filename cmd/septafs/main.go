@@ -2,6 +2,7 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"os"
 
@@ -10,10 +11,44 @@ import (
 	"github.com/jnschaeffer/septafs/septa"
 )
 
-var mountpoint string
+var (
+	mountpoint string
+	backend    string
+	endpoint   string
+
+	vehiclePositionsURL string
+	alertsURL           string
+)
 
 func init() {
 	flag.StringVar(&mountpoint, "mountpoint", "", "mount point for septafs")
+	flag.StringVar(&backend, "backend", "hackathon",
+		`transit data backend to use: "hackathon" or "gtfs-realtime"`)
+	flag.StringVar(&endpoint, "endpoint", "http://www3.septa.org",
+		`hackathon API endpoint (only used with -backend=hackathon)`)
+	flag.StringVar(&vehiclePositionsURL, "vehicle-positions-url", "",
+		`GTFS-Realtime VehiclePositions feed URL (only used with `+
+			`-backend=gtfs-realtime)`)
+	flag.StringVar(&alertsURL, "alerts-url", "",
+		`GTFS-Realtime Alerts feed URL (only used with -backend=gtfs-realtime)`)
+}
+
+// newClient returns the Client backend selected by -backend.
+func newClient() (septa.Client, error) {
+	switch backend {
+	case "hackathon":
+		return septa.NewHTTPClient(endpoint), nil
+	case "gtfs-realtime":
+		if vehiclePositionsURL == "" || alertsURL == "" {
+			return nil, fmt.Errorf(
+				"-backend=gtfs-realtime requires -vehicle-positions-url " +
+					"and -alerts-url")
+		}
+
+		return septa.NewGTFSRealtimeClient(vehiclePositionsURL, alertsURL), nil
+	default:
+		return nil, fmt.Errorf("unknown -backend %q", backend)
+	}
 }
 
 func main() {
@@ -24,7 +59,12 @@ func main() {
 		os.Exit(2)
 	}
 
-	c, err := fuse.Mount(
+	backendClient, err := newClient()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	conn, err := fuse.Mount(
 		mountpoint,
 		fuse.FSName("septafs"),
 		fuse.Subtype("septa"),
@@ -34,14 +74,19 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer c.Close()
+	defer conn.Close()
+
+	client := septa.NewCachingClient(backendClient)
+	client.StartBackgroundRefresh(septa.DefaultLocationsTTL)
+	defer client.Close()
 
-	if err = fs.Serve(c, septa.FS{}); err != nil {
+	server := fs.New(conn, nil)
+	if err = server.Serve(septa.NewFS(client, server)); err != nil {
 		log.Fatal(err)
 	}
 
-	<-c.Ready
-	if err = c.MountError; err != nil {
+	<-conn.Ready
+	if err = conn.MountError; err != nil {
 		log.Fatal(err)
 	}
 }
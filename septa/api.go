@@ -2,13 +2,16 @@ package septa
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/net/html"
 )
@@ -113,33 +116,200 @@ func (bts *busTrolleys) UnmarshalJSON(b []byte) (err error) {
 	return
 }
 
+// Train represents the position of a Regional Rail train.
+type Train struct {
+	Lat     float64
+	Lng     float64
+	Line    string `json:"line"`
+	Consist string `json:"consist"`
+	Delay   int
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (t *Train) UnmarshalJSON(b []byte) (err error) {
+	type trainJSON Train
+
+	var tj struct {
+		Lat   floatFromString `json:"lat"`
+		Lng   floatFromString `json:"lon"`
+		Delay intFromString   `json:"late"`
+		trainJSON
+	}
+
+	if err = json.Unmarshal(b, &tj); err != nil {
+		return
+	}
+
+	*t = Train(tj.trainJSON)
+	t.Lat = float64(tj.Lat)
+	t.Lng = float64(tj.Lng)
+	t.Delay = int(tj.Delay)
+
+	return
+}
+
+func (t Train) String() (s string) {
+	base := `Latitude: %f
+Longitude: %f
+Line: %s
+Consist: %s
+Delay: %d minutes
+`
+	s = fmt.Sprintf(base, t.Lat, t.Lng, t.Line, t.Consist, t.Delay)
+
+	return
+}
+
 // RouteAlert represents an alert on a SEPTA route.
 type RouteAlert struct {
 	RouteName       string `json:"route_name"`
 	CurrentMessage  string `json:"current_message"`
 	AdvisoryMessage string `json:"advisory_message"`
+
+	// CurrentMessageHTML and AdvisoryMessageHTML hold the message text
+	// before HTML stripping, for consumers that want to preserve
+	// SEPTA's original markup. They're excluded from JSON so a plain
+	// json.Marshal of RouteAlert doesn't duplicate the stripped fields.
+	CurrentMessageHTML  string `json:"-"`
+	AdvisoryMessageHTML string `json:"-"`
+}
+
+// Arrival represents a single scheduled arrival at a stop.
+type Arrival struct {
+	Route         string `json:"route_id"`
+	DirectionName string `json:"direction_name"`
+	ScheduledTime string `json:"sched_time"`
+	Status        string `json:"status"`
+}
+
+func (a Arrival) String() (s string) {
+	base := `Route: %s
+Direction: %s
+Scheduled: %s
+Status: %s
+`
+	s = fmt.Sprintf(base, a.Route, a.DirectionName, a.ScheduledTime, a.Status)
+
+	return
 }
 
-// HTTPClient implements SEPTA API functionality.
+// NextToArrive represents an upcoming trip between two stations.
+type NextToArrive struct {
+	OrigLine    string `json:"orig_line"`
+	OrigDeparts string `json:"orig_departure_time"`
+	OrigArrives string `json:"orig_arrival_time"`
+	OrigDelay   string `json:"orig_delay"`
+	TerrLine    string `json:"terr_line"`
+	TerrDeparts string `json:"terr_departure_time"`
+	TerrArrives string `json:"terr_arrival_time"`
+	TerrDelay   string `json:"terr_delay"`
+	IsDirect    string `json:"isdirect"`
+}
+
+func (n NextToArrive) String() (s string) {
+	base := `Orig Line: %s
+Orig Departs: %s
+Orig Arrives: %s
+Orig Delay: %s
+Terr Line: %s
+Terr Departs: %s
+Terr Arrives: %s
+Terr Delay: %s
+Direct: %s
+`
+	s = fmt.Sprintf(base, n.OrigLine, n.OrigDeparts, n.OrigArrives,
+		n.OrigDelay, n.TerrLine, n.TerrDeparts, n.TerrArrives, n.TerrDelay,
+		n.IsDirect)
+
+	return
+}
+
+// Stop represents a SEPTA transit stop.
+type Stop struct {
+	ID    string
+	Name  string
+	Lat   float64
+	Lng   float64
+	Lines []string
+}
+
+// DefaultReadTimeout is the ReadTimeout NewHTTPClient applies by default,
+// so a hung SEPTA endpoint can't block a FUSE ReadAll (and the kernel
+// along with it) forever.
+const DefaultReadTimeout = 10 * time.Second
+
+// HTTPClient implements Client against SEPTA's hackathon API.
 type HTTPClient struct {
 	endpoint string
+
+	// ReadTimeout bounds how long a single outbound request may take
+	// before its context is canceled. Zero means no timeout.
+	ReadTimeout time.Duration
+
+	// WriteTimeout bounds how long a single outbound request with a body
+	// may take before its context is canceled. Zero means no timeout.
+	// HTTPClient has no write paths today; this exists so future ones
+	// share the same deadline plumbing as ReadTimeout.
+	WriteTimeout time.Duration
 }
 
-// TransitView returns the current transit status for the given route.
-func (c HTTPClient) TransitView(route string) (bts []BusTrolley, err error) {
-	url := fmt.Sprintf("%s/hackathon/TransitView/%s", c.endpoint, route)
+// NewHTTPClient returns an HTTPClient that talks to the hackathon API
+// rooted at endpoint, with ReadTimeout set to DefaultReadTimeout. Callers
+// that want a different timeout, or none, can override ReadTimeout on
+// the returned value.
+func NewHTTPClient(endpoint string) HTTPClient {
+	return HTTPClient{endpoint: endpoint, ReadTimeout: DefaultReadTimeout}
+}
 
-	var resp *http.Response
-	if resp, err = http.Get(url); err != nil {
+var _ Client = HTTPClient{}
+
+// withDeadline derives a child of ctx that is canceled when either ctx is
+// canceled or timeout elapses. A timeout <= 0 leaves ctx otherwise
+// untouched.
+func (c HTTPClient) withDeadline(ctx context.Context,
+	timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// getCtx performs a GET request against url, canceling it if ctx is done
+// or c.ReadTimeout elapses, and returns the response body.
+func (c HTTPClient) getCtx(ctx context.Context, url string) (
+	body []byte, err error) {
+	ctx, cancel := c.withDeadline(ctx, c.ReadTimeout)
+	defer cancel()
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, "GET", url, nil); err != nil {
 		return
 	}
 
-	var ret []byte
-	if ret, err = ioutil.ReadAll(resp.Body); err != nil {
+	var resp *http.Response
+	if resp, err = http.DefaultClient.Do(req); err != nil {
 		return
 	}
+	defer resp.Body.Close()
+
+	body, err = ioutil.ReadAll(resp.Body)
+
+	return
+}
+
+// TransitView returns the current transit status for the given route.
+func (c HTTPClient) TransitView(route string) ([]BusTrolley, error) {
+	return c.TransitViewCtx(context.Background(), route)
+}
 
-	if err = resp.Body.Close(); err != nil {
+// TransitViewCtx is TransitView with a caller-supplied context.
+func (c HTTPClient) TransitViewCtx(ctx context.Context, route string) (
+	bts []BusTrolley, err error) {
+	url := fmt.Sprintf("%s/hackathon/TransitView/%s", c.endpoint, route)
+
+	var ret []byte
+	if ret, err = c.getCtx(ctx, url); err != nil {
 		return
 	}
 
@@ -202,26 +372,35 @@ func (c HTTPClient) stripHTML(s string) (t string, err error) {
 }
 
 // RouteAlerts returns alerts for the given route.
-func (c HTTPClient) RouteAlerts(route string) (rts []RouteAlert, err error) {
-	url := fmt.Sprintf("%s/hackathon/Alerts/get_alert_data.php?req1=%s",
-		c.endpoint, route)
+func (c HTTPClient) RouteAlerts(route string, isBus bool) ([]RouteAlert,
+	error) {
+	return c.RouteAlertsCtx(context.Background(), route, isBus)
+}
 
-	var resp *http.Response
-	if resp, err = http.Get(url); err != nil {
-		return
+// RouteAlertsCtx is RouteAlerts with a caller-supplied context. The
+// hackathon Alerts API keys its req1 parameter on vehicle kind as well as
+// route, so isBus picks between the "bus_route_" and "trolley_route_"
+// prefixes this endpoint expects.
+func (c HTTPClient) RouteAlertsCtx(ctx context.Context, route string,
+	isBus bool) (rts []RouteAlert, err error) {
+	prefix := "trolley_route_"
+	if isBus {
+		prefix = "bus_route_"
 	}
 
-	var ret []byte
-	if ret, err = ioutil.ReadAll(resp.Body); err != nil {
-		return
-	}
+	url := fmt.Sprintf("%s/hackathon/Alerts/get_alert_data.php?req1=%s%s",
+		c.endpoint, prefix, route)
 
-	if err = resp.Body.Close(); err != nil {
+	var ret []byte
+	if ret, err = c.getCtx(ctx, url); err != nil {
 		return
 	}
 
 	err = json.Unmarshal(ret, &rts)
 	for i := range rts {
+		rts[i].AdvisoryMessageHTML = rts[i].AdvisoryMessage
+		rts[i].CurrentMessageHTML = rts[i].CurrentMessage
+
 		rts[i].AdvisoryMessage, err = c.stripHTML(rts[i].AdvisoryMessage)
 		if err != nil {
 			return
@@ -234,3 +413,82 @@ func (c HTTPClient) RouteAlerts(route string) (rts []RouteAlert, err error) {
 
 	return
 }
+
+// TrainView returns the current position of every Regional Rail train.
+func (c HTTPClient) TrainView() ([]Train, error) {
+	return c.TrainViewCtx(context.Background())
+}
+
+// TrainViewCtx is TrainView with a caller-supplied context.
+func (c HTTPClient) TrainViewCtx(ctx context.Context) (trains []Train,
+	err error) {
+	url := fmt.Sprintf("%s/hackathon/TrainView/index.php", c.endpoint)
+
+	var ret []byte
+	if ret, err = c.getCtx(ctx, url); err != nil {
+		return
+	}
+
+	err = json.Unmarshal(ret, &trains)
+
+	return
+}
+
+// Arrivals returns scheduled arrivals for the given stop ID.
+func (c HTTPClient) Arrivals(stopID string, results int) ([]Arrival, error) {
+	return c.ArrivalsCtx(context.Background(), stopID, results)
+}
+
+// ArrivalsCtx is Arrivals with a caller-supplied context.
+func (c HTTPClient) ArrivalsCtx(ctx context.Context, stopID string,
+	results int) (arrs []Arrival, err error) {
+	url := fmt.Sprintf("%s/hackathon/Arrivals/%s?req2=%d", c.endpoint, stopID,
+		results)
+
+	var ret []byte
+	if ret, err = c.getCtx(ctx, url); err != nil {
+		return
+	}
+
+	var directions []map[string][]Arrival
+	if err = json.Unmarshal(ret, &directions); err != nil {
+		return
+	}
+
+	for _, d := range directions {
+		for _, dirArrivals := range d {
+			arrs = append(arrs, dirArrivals...)
+		}
+	}
+
+	return
+}
+
+// NextToArrive returns the next vehicles scheduled to arrive at dest from
+// orig.
+func (c HTTPClient) NextToArrive(orig, dest string, results int) (
+	[]NextToArrive, error) {
+	return c.NextToArriveCtx(context.Background(), orig, dest, results)
+}
+
+// NextToArriveCtx is NextToArrive with a caller-supplied context.
+func (c HTTPClient) NextToArriveCtx(ctx context.Context, orig, dest string,
+	results int) (ntas []NextToArrive, err error) {
+	url := fmt.Sprintf("%s/hackathon/NextToArrive/index.php?req1=%s&req2=%s&req3=%d",
+		c.endpoint, url.QueryEscape(orig), url.QueryEscape(dest), results)
+
+	var ret []byte
+	if ret, err = c.getCtx(ctx, url); err != nil {
+		return
+	}
+
+	err = json.Unmarshal(ret, &ntas)
+
+	return
+}
+
+// Stops returns the list of known transit stops, loaded once from the
+// bundled GTFS stops.txt.
+func (c HTTPClient) Stops() ([]Stop, error) {
+	return loadStops()
+}
@@ -0,0 +1,44 @@
+package septa
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPClientRouteAlertsCtxPrefixesByVehicleKind(t *testing.T) {
+	tests := []struct {
+		name     string
+		route    string
+		isBus    bool
+		wantReq1 string
+	}{
+		{"bus", "42", true, "bus_route_42"},
+		{"trolley", "10", false, "trolley_route_10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotReq1 string
+
+			srv := httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					gotReq1 = r.URL.Query().Get("req1")
+					w.Write([]byte("[]"))
+				}))
+			defer srv.Close()
+
+			c := NewHTTPClient(srv.URL)
+
+			if _, err := c.RouteAlertsCtx(context.Background(), tt.route,
+				tt.isBus); err != nil {
+				t.Fatalf("RouteAlertsCtx: %v", err)
+			}
+
+			if gotReq1 != tt.wantReq1 {
+				t.Errorf("req1 = %q, want %q", gotReq1, tt.wantReq1)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package septa
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultLocationsTTL is the default TTL CachingClient applies to
+// TransitView results.
+const DefaultLocationsTTL = 15 * time.Second
+
+// DefaultAlertsTTL is the default TTL CachingClient applies to RouteAlerts
+// results.
+const DefaultAlertsTTL = 5 * time.Minute
+
+type locationEntry struct {
+	bts       []BusTrolley
+	fetchedAt time.Time
+}
+
+type alertEntry struct {
+	rts       []RouteAlert
+	fetchedAt time.Time
+}
+
+// alertKey identifies a cached alerts fetch. route alone isn't always
+// enough: some Client implementations key their endpoint on vehicle kind
+// as well as route, so a bus route and a trolley route with the same
+// numeric id must not share a cache entry.
+type alertKey struct {
+	route string
+	isBus bool
+}
+
+// cacheKey returns the string form of k used to key the alerts
+// singleflight group.
+func (k alertKey) cacheKey() string {
+	if k.isBus {
+		return "bus:" + k.route
+	}
+
+	return "trolley:" + k.route
+}
+
+// CachingClient wraps a Client with an in-memory TTL cache keyed by route
+// (and, for alerts, vehicle kind), coalescing concurrent misses for the same route with singleflight so a
+// burst of FUSE reads for one route only triggers one fetch.
+type CachingClient struct {
+	Client
+
+	// LocationsTTL is how long a TransitView result stays fresh.
+	LocationsTTL time.Duration
+
+	// AlertsTTL is how long a RouteAlerts result stays fresh.
+	AlertsTTL time.Duration
+
+	mu        sync.Mutex
+	locations map[string]*locationEntry
+	alerts    map[alertKey]*alertEntry
+
+	locationsGroup singleflight.Group
+	alertsGroup    singleflight.Group
+
+	hits   uint64
+	misses uint64
+
+	stop chan struct{}
+}
+
+// NewCachingClient returns a CachingClient wrapping c, using
+// DefaultLocationsTTL and DefaultAlertsTTL.
+func NewCachingClient(c Client) *CachingClient {
+	return &CachingClient{
+		Client:       c,
+		LocationsTTL: DefaultLocationsTTL,
+		AlertsTTL:    DefaultAlertsTTL,
+		locations:    make(map[string]*locationEntry),
+		alerts:       make(map[alertKey]*alertEntry),
+		stop:         make(chan struct{}),
+	}
+}
+
+var _ Client = (*CachingClient)(nil)
+
+// Hits returns the number of reads served from cache.
+func (c *CachingClient) Hits() uint64 {
+	return atomic.LoadUint64(&c.hits)
+}
+
+// Misses returns the number of reads that required a fetch from the
+// underlying Client.
+func (c *CachingClient) Misses() uint64 {
+	return atomic.LoadUint64(&c.misses)
+}
+
+// StartBackgroundRefresh launches a goroutine that, every interval,
+// proactively refetches routes whose cache entries are stale but were
+// fetched recently (see refreshStale), so a subsequent read rarely has to
+// wait on an upstream round trip. Close stops the goroutine.
+func (c *CachingClient) StartBackgroundRefresh(interval time.Duration) {
+	go c.refreshLoop(interval)
+}
+
+// Close stops any goroutine started by StartBackgroundRefresh.
+func (c *CachingClient) Close() {
+	close(c.stop)
+}
+
+func (c *CachingClient) refreshLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.refreshStale()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// refreshStale refetches every cached route whose entry is past its TTL but
+// was fetched within the last two TTL windows. Entries record only when
+// they were fetched, not when they were last read, and a refetch here
+// resets that time, so a route stays in the refresh set as long as each
+// tick lands inside its window, whether or not anyone still reads it.
+func (c *CachingClient) refreshStale() {
+	now := time.Now()
+
+	c.mu.Lock()
+	locationRoutes := make([]string, 0, len(c.locations))
+	for route, e := range c.locations {
+		age := now.Sub(e.fetchedAt)
+		if age >= c.LocationsTTL && age < 2*c.LocationsTTL {
+			locationRoutes = append(locationRoutes, route)
+		}
+	}
+
+	alertRoutes := make([]alertKey, 0, len(c.alerts))
+	for key, e := range c.alerts {
+		age := now.Sub(e.fetchedAt)
+		if age >= c.AlertsTTL && age < 2*c.AlertsTTL {
+			alertRoutes = append(alertRoutes, key)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, route := range locationRoutes {
+		c.fetchLocations(context.Background(), route)
+	}
+
+	for _, key := range alertRoutes {
+		c.fetchAlerts(context.Background(), key.route, key.isBus)
+	}
+}
+
+// TransitView returns the current transit status for the given route.
+func (c *CachingClient) TransitView(route string) ([]BusTrolley, error) {
+	return c.TransitViewCtx(context.Background(), route)
+}
+
+// TransitViewCtx is TransitView with a caller-supplied context.
+func (c *CachingClient) TransitViewCtx(ctx context.Context, route string) (
+	[]BusTrolley, error) {
+	c.mu.Lock()
+	e, ok := c.locations[route]
+	c.mu.Unlock()
+
+	if ok && time.Since(e.fetchedAt) < c.LocationsTTL {
+		atomic.AddUint64(&c.hits, 1)
+		return e.bts, nil
+	}
+
+	atomic.AddUint64(&c.misses, 1)
+
+	// The fetch itself runs on a context independent of ctx: it's shared
+	// by every caller the singleflight call coalesces, so one caller's
+	// canceled read must not cancel the fetch out from under the others.
+	// ctx only bounds this caller's wait on the shared result.
+	ch := c.locationsGroup.DoChan(route, func() (interface{}, error) {
+		return c.fetchLocations(context.Background(), route)
+	})
+
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.([]BusTrolley), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *CachingClient) fetchLocations(ctx context.Context, route string) (
+	[]BusTrolley, error) {
+	bts, err := c.Client.TransitViewCtx(ctx, route)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.locations[route] = &locationEntry{bts: bts, fetchedAt: time.Now()}
+	c.mu.Unlock()
+
+	return bts, nil
+}
+
+// RouteAlerts returns alerts for the given route.
+func (c *CachingClient) RouteAlerts(route string, isBus bool) (
+	[]RouteAlert, error) {
+	return c.RouteAlertsCtx(context.Background(), route, isBus)
+}
+
+// RouteAlertsCtx is RouteAlerts with a caller-supplied context.
+func (c *CachingClient) RouteAlertsCtx(ctx context.Context, route string,
+	isBus bool) ([]RouteAlert, error) {
+	key := alertKey{route: route, isBus: isBus}
+
+	c.mu.Lock()
+	e, ok := c.alerts[key]
+	c.mu.Unlock()
+
+	if ok && time.Since(e.fetchedAt) < c.AlertsTTL {
+		atomic.AddUint64(&c.hits, 1)
+		return e.rts, nil
+	}
+
+	atomic.AddUint64(&c.misses, 1)
+
+	// See the locations fetch above: the shared fetch runs on its own
+	// context so one caller's cancellation can't take down the others.
+	ch := c.alertsGroup.DoChan(key.cacheKey(), func() (interface{}, error) {
+		return c.fetchAlerts(context.Background(), route, isBus)
+	})
+
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.([]RouteAlert), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *CachingClient) fetchAlerts(ctx context.Context, route string,
+	isBus bool) ([]RouteAlert, error) {
+	rts, err := c.Client.RouteAlertsCtx(ctx, route, isBus)
+	if err != nil {
+		return nil, err
+	}
+
+	key := alertKey{route: route, isBus: isBus}
+
+	c.mu.Lock()
+	c.alerts[key] = &alertEntry{rts: rts, fetchedAt: time.Now()}
+	c.mu.Unlock()
+
+	return rts, nil
+}
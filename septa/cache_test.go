@@ -0,0 +1,318 @@
+package septa
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClient is a Client whose TransitView/RouteAlerts methods are
+// configurable per test and count how many times they're invoked, so
+// tests can assert on CachingClient's caching and coalescing behavior
+// without a real upstream.
+type fakeClient struct {
+	transitCalls uint64
+	alertsCalls  uint64
+
+	transitFunc func(ctx context.Context, route string) ([]BusTrolley, error)
+	alertsFunc  func(ctx context.Context, route string, isBus bool) (
+		[]RouteAlert, error)
+}
+
+var _ Client = (*fakeClient)(nil)
+
+func (f *fakeClient) TransitView(route string) ([]BusTrolley, error) {
+	return f.TransitViewCtx(context.Background(), route)
+}
+
+func (f *fakeClient) TransitViewCtx(ctx context.Context, route string) (
+	[]BusTrolley, error) {
+	atomic.AddUint64(&f.transitCalls, 1)
+	if f.transitFunc == nil {
+		return nil, nil
+	}
+
+	return f.transitFunc(ctx, route)
+}
+
+func (f *fakeClient) RouteAlerts(route string, isBus bool) ([]RouteAlert,
+	error) {
+	return f.RouteAlertsCtx(context.Background(), route, isBus)
+}
+
+func (f *fakeClient) RouteAlertsCtx(ctx context.Context, route string,
+	isBus bool) ([]RouteAlert, error) {
+	atomic.AddUint64(&f.alertsCalls, 1)
+	if f.alertsFunc == nil {
+		return nil, nil
+	}
+
+	return f.alertsFunc(ctx, route, isBus)
+}
+
+func (f *fakeClient) TrainView() ([]Train, error) { return nil, nil }
+func (f *fakeClient) TrainViewCtx(ctx context.Context) ([]Train, error) {
+	return nil, nil
+}
+
+func (f *fakeClient) Arrivals(stopID string, results int) ([]Arrival,
+	error) {
+	return nil, nil
+}
+
+func (f *fakeClient) ArrivalsCtx(ctx context.Context, stopID string,
+	results int) ([]Arrival, error) {
+	return nil, nil
+}
+
+func (f *fakeClient) NextToArrive(orig, dest string, results int) (
+	[]NextToArrive, error) {
+	return nil, nil
+}
+
+func (f *fakeClient) NextToArriveCtx(ctx context.Context, orig, dest string,
+	results int) ([]NextToArrive, error) {
+	return nil, nil
+}
+
+func (f *fakeClient) Stops() ([]Stop, error) { return nil, nil }
+
+func TestCachingClientTransitViewCachesWithinTTL(t *testing.T) {
+	fc := &fakeClient{
+		transitFunc: func(ctx context.Context, route string) (
+			[]BusTrolley, error) {
+			return []BusTrolley{{Direction: route}}, nil
+		},
+	}
+
+	c := NewCachingClient(fc)
+	c.LocationsTTL = time.Minute
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.TransitViewCtx(context.Background(), "42"); err != nil {
+			t.Fatalf("TransitViewCtx: %v", err)
+		}
+	}
+
+	if got := atomic.LoadUint64(&fc.transitCalls); got != 1 {
+		t.Errorf("underlying TransitViewCtx called %d times, want 1", got)
+	}
+	if got := c.Hits(); got != 2 {
+		t.Errorf("Hits() = %d, want 2", got)
+	}
+	if got := c.Misses(); got != 1 {
+		t.Errorf("Misses() = %d, want 1", got)
+	}
+}
+
+func TestCachingClientTransitViewRefetchesAfterTTL(t *testing.T) {
+	fc := &fakeClient{}
+	c := NewCachingClient(fc)
+	c.LocationsTTL = time.Millisecond
+
+	if _, err := c.TransitViewCtx(context.Background(), "42"); err != nil {
+		t.Fatalf("TransitViewCtx: %v", err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, err := c.TransitViewCtx(context.Background(), "42"); err != nil {
+		t.Fatalf("TransitViewCtx: %v", err)
+	}
+
+	if got := atomic.LoadUint64(&fc.transitCalls); got != 2 {
+		t.Errorf("underlying TransitViewCtx called %d times, want 2", got)
+	}
+}
+
+// TestCachingClientRefreshStaleRefetchesRecentlyReadRoutes covers
+// refreshStale directly: an entry that's past its TTL but was fetched
+// within the last two TTL windows should be refetched in place, without
+// any caller driving a read.
+func TestCachingClientRefreshStaleRefetchesRecentlyReadRoutes(t *testing.T) {
+	fc := &fakeClient{
+		transitFunc: func(ctx context.Context, route string) (
+			[]BusTrolley, error) {
+			return []BusTrolley{{Direction: route}}, nil
+		},
+	}
+
+	c := NewCachingClient(fc)
+	c.LocationsTTL = 10 * time.Millisecond
+
+	if _, err := c.TransitViewCtx(context.Background(), "42"); err != nil {
+		t.Fatalf("seeding TransitViewCtx: %v", err)
+	}
+
+	c.mu.Lock()
+	firstFetch := c.locations["42"].fetchedAt
+	// Backdate the entry so it looks stale-but-recently-read: past
+	// LocationsTTL, but still inside the two-TTL window refreshStale
+	// treats as worth refreshing.
+	c.locations["42"].fetchedAt = time.Now().Add(-3 * c.LocationsTTL / 2)
+	c.mu.Unlock()
+
+	c.refreshStale()
+
+	c.mu.Lock()
+	refreshedAt := c.locations["42"].fetchedAt
+	c.mu.Unlock()
+
+	if !refreshedAt.After(firstFetch) {
+		t.Errorf("refreshStale didn't refetch: fetchedAt = %v, want after %v",
+			refreshedAt, firstFetch)
+	}
+	if got := atomic.LoadUint64(&fc.transitCalls); got != 2 {
+		t.Errorf("underlying TransitViewCtx called %d times, want 2", got)
+	}
+}
+
+// TestCachingClientStartBackgroundRefreshRefetchesWithoutACallerRead
+// exercises the end-to-end background-refresh goroutine, not just
+// refreshStale's selection logic.
+func TestCachingClientStartBackgroundRefreshRefetchesWithoutACallerRead(t *testing.T) {
+	var calls uint64
+	fc := &fakeClient{
+		transitFunc: func(ctx context.Context, route string) (
+			[]BusTrolley, error) {
+			atomic.AddUint64(&calls, 1)
+			return []BusTrolley{{Direction: route}}, nil
+		},
+	}
+
+	c := NewCachingClient(fc)
+	c.LocationsTTL = 40 * time.Millisecond
+
+	if _, err := c.TransitViewCtx(context.Background(), "42"); err != nil {
+		t.Fatalf("seeding TransitViewCtx: %v", err)
+	}
+
+	c.StartBackgroundRefresh(5 * time.Millisecond)
+	defer c.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadUint64(&calls) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if got := atomic.LoadUint64(&calls); got < 2 {
+		t.Fatalf("background refresh fetched %d times without a caller "+
+			"read, want at least 2", got)
+	}
+}
+
+func TestCachingClientRouteAlertsKeysByVehicleKind(t *testing.T) {
+	fc := &fakeClient{}
+	c := NewCachingClient(fc)
+	c.AlertsTTL = time.Minute
+
+	if _, err := c.RouteAlertsCtx(context.Background(), "42", true); err != nil {
+		t.Fatalf("RouteAlertsCtx(bus): %v", err)
+	}
+	if _, err := c.RouteAlertsCtx(context.Background(), "42", false); err != nil {
+		t.Fatalf("RouteAlertsCtx(trolley): %v", err)
+	}
+
+	// Route 42 as a bus and route 42 as a trolley are different
+	// upstream endpoints, so they must not share a cache entry.
+	if got := atomic.LoadUint64(&fc.alertsCalls); got != 2 {
+		t.Errorf("underlying RouteAlertsCtx called %d times, want 2", got)
+	}
+}
+
+func TestCachingClientTransitViewCoalescesConcurrentFetches(t *testing.T) {
+	release := make(chan struct{})
+	fc := &fakeClient{
+		transitFunc: func(ctx context.Context, route string) (
+			[]BusTrolley, error) {
+			<-release
+			return []BusTrolley{{Direction: route}}, nil
+		},
+	}
+
+	c := NewCachingClient(fc)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.TransitViewCtx(context.Background(), "42")
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadUint64(&fc.transitCalls); got != 1 {
+		t.Errorf("underlying TransitViewCtx called %d times, want 1", got)
+	}
+}
+
+// TestCachingClientTransitViewSurvivesCallerCancellation guards against a
+// singleflight-shared fetch being canceled by whichever caller happened
+// to trigger it: a follower that joins the same in-flight fetch must
+// still get its result even after the leader that triggered the fetch is
+// canceled.
+func TestCachingClientTransitViewSurvivesCallerCancellation(t *testing.T) {
+	started := make(chan struct{})
+	var startedOnce sync.Once
+	release := make(chan struct{})
+
+	fc := &fakeClient{
+		transitFunc: func(ctx context.Context, route string) (
+			[]BusTrolley, error) {
+			startedOnce.Do(func() { close(started) })
+			select {
+			case <-release:
+				return []BusTrolley{{Direction: route}}, nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		},
+	}
+
+	c := NewCachingClient(fc)
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderDone := make(chan struct{})
+	go func() {
+		defer close(leaderDone)
+		c.TransitViewCtx(leaderCtx, "42")
+	}()
+
+	<-started // the leader's fetch is now in flight
+
+	var (
+		bts []BusTrolley
+		err error
+	)
+	followerDone := make(chan struct{})
+	go func() {
+		defer close(followerDone)
+		bts, err = c.TransitViewCtx(context.Background(), "42")
+	}()
+
+	// Give the follower a chance to join the in-flight singleflight call
+	// before the leader is canceled.
+	time.Sleep(5 * time.Millisecond)
+
+	cancelLeader()
+	<-leaderDone
+
+	close(release)
+	<-followerDone
+
+	if err != nil {
+		t.Fatalf("TransitViewCtx for the uncanceled follower: %v", err)
+	}
+	if len(bts) != 1 {
+		t.Fatalf("got %d results, want 1", len(bts))
+	}
+	if got := atomic.LoadUint64(&fc.transitCalls); got != 1 {
+		t.Errorf("underlying TransitViewCtx called %d times, want 1", got)
+	}
+}
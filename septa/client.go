@@ -0,0 +1,57 @@
+package septa
+
+import "context"
+
+// Client defines the set of SEPTA transit data operations used by septafs.
+// Implementations may talk to SEPTA's hackathon API directly, a
+// GTFS-Realtime feed, or a wrapper (caching, instrumentation, test fakes)
+// around either.
+type Client interface {
+	// TransitView returns the current transit status for the given route.
+	TransitView(route string) ([]BusTrolley, error)
+
+	// TransitViewCtx is TransitView with a caller-supplied context. A
+	// canceled ctx makes the call return an error rather than block until
+	// the underlying request completes. HTTPClient also aborts that
+	// request; CachingClient lets a fetch it shares with other callers
+	// run to completion.
+	TransitViewCtx(ctx context.Context, route string) ([]BusTrolley, error)
+
+	// RouteAlerts returns alerts for the given route. route is the plain
+	// GTFS route id, the same one passed to TransitView; isBus
+	// disambiguates it from a trolley route sharing the same numeric id,
+	// for implementations whose endpoint needs to know the vehicle kind.
+	// Implementations that don't need the distinction may ignore isBus.
+	RouteAlerts(route string, isBus bool) ([]RouteAlert, error)
+
+	// RouteAlertsCtx is RouteAlerts with a caller-supplied context. A
+	// canceled ctx makes the call return an error rather than block until
+	// the underlying request completes, as for TransitViewCtx.
+	RouteAlertsCtx(ctx context.Context, route string, isBus bool) (
+		[]RouteAlert, error)
+
+	// TrainView returns the current position of every Regional Rail
+	// train.
+	TrainView() ([]Train, error)
+
+	// TrainViewCtx is TrainView with a caller-supplied context.
+	TrainViewCtx(ctx context.Context) ([]Train, error)
+
+	// Arrivals returns scheduled arrivals for the given stop ID.
+	Arrivals(stopID string, results int) ([]Arrival, error)
+
+	// ArrivalsCtx is Arrivals with a caller-supplied context.
+	ArrivalsCtx(ctx context.Context, stopID string, results int) (
+		[]Arrival, error)
+
+	// NextToArrive returns the next vehicles scheduled to arrive at dest
+	// from orig.
+	NextToArrive(orig, dest string, results int) ([]NextToArrive, error)
+
+	// NextToArriveCtx is NextToArrive with a caller-supplied context.
+	NextToArriveCtx(ctx context.Context, orig, dest string, results int) (
+		[]NextToArrive, error)
+
+	// Stops returns the list of known transit stops.
+	Stops() ([]Stop, error)
+}
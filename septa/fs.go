@@ -2,16 +2,30 @@
 package septa
 
 import (
-	"fmt"
+	"context"
+	"encoding/json"
 	"log"
 	"os"
+	"time"
 
 	"bazil.org/fuse"
 	"bazil.org/fuse/fs"
 )
 
-var client = HTTPClient{
-	endpoint: "http://www3.septa.org",
+// ctxFromIntr returns a context that is canceled either when intr fires or
+// when the returned cancel func is called, whichever comes first.
+func ctxFromIntr(intr fs.Intr) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		select {
+		case <-intr:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
 }
 
 var tRouteIDs = []string{"10", "11", "13", "15", "34", "36", "101", "102"}
@@ -28,23 +42,53 @@ var bRouteIDs = []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "12",
 	"310"}
 
 // FS implements the SEPTA file system, septafs.
-type FS struct{}
+type FS struct {
+	client Client
+	server *fs.Server
+
+	// NotifyInterval is how often the FUSE invalidation notifier polls
+	// upstream for route changes. A zero server disables the notifier
+	// regardless of NotifyInterval.
+	NotifyInterval time.Duration
+}
 
-// Root returns a rootDir as the file system root.
-func (FS) Root() (n fs.Node, err fuse.Error) {
-	n = rootDir{
-		trolleyNode: newBusTrolleyRoutes(tRouteIDs, false, 2),
-		busNode:     newBusTrolleyRoutes(bRouteIDs, true, 3),
+// NewFS returns an FS that serves transit data from c. server, if
+// non-nil, is used to push FUSE cache invalidations to the kernel as
+// upstream data changes; pass the *fs.Server returned by fs.New for the
+// same connection this FS will be served on.
+func NewFS(c Client, server *fs.Server) FS {
+	return FS{client: c, server: server, NotifyInterval: DefaultNotifyInterval}
+}
+
+// Root returns a rootDir as the file system root, starting the
+// invalidation notifier if f.server is set.
+func (f FS) Root() (n fs.Node, err fuse.Error) {
+	root := rootDir{
+		trolleyNode: newBusTrolleyRoutes(f.client, tRouteIDs, false, 2),
+		busNode:     newBusTrolleyRoutes(f.client, bRouteIDs, true, 3),
+		railNode:    newRailLines(f.client),
+		ntaNode:     newNtaDir(f.client, 5),
+		stopsNode:   newStopsLines(f.client),
+	}
+
+	if f.server != nil {
+		go newNotifier(f.client, f.server, root).run(context.Background(),
+			f.NotifyInterval)
 	}
 
+	n = root
+
 	return
 }
 
 // rootDir implements Node and Handle for the SEPTA file system. At the root
 // of septafs are the following directories:
 type rootDir struct {
-	trolleyNode busTrolleyRoutes
-	busNode     busTrolleyRoutes
+	trolleyNode *busTrolleyRoutes
+	busNode     *busTrolleyRoutes
+	railNode    *railLines
+	ntaNode     *ntaDir
+	stopsNode   *stopsLines
 }
 
 // Attr returns the rootDir attributes.
@@ -61,6 +105,9 @@ func (rootDir) ReadDir(intr fs.Intr) (dirs []fuse.Dirent, err fuse.Error) {
 	dirs = []fuse.Dirent{
 		{Name: "trolley", Type: fuse.DT_Dir},
 		{Name: "bus", Type: fuse.DT_Dir},
+		{Name: "rail", Type: fuse.DT_Dir},
+		{Name: "nta", Type: fuse.DT_Dir},
+		{Name: "stops", Type: fuse.DT_Dir},
 	}
 
 	return
@@ -74,6 +121,12 @@ func (r rootDir) Lookup(name string, intr fs.Intr) (n fs.Node,
 		n = r.trolleyNode
 	case "bus":
 		n = r.busNode
+	case "rail":
+		n = r.railNode
+	case "nta":
+		n = r.ntaNode
+	case "stops":
+		n = r.stopsNode
 	default:
 		err = fuse.ENOENT
 	}
@@ -81,35 +134,40 @@ func (r rootDir) Lookup(name string, intr fs.Intr) (n fs.Node,
 	return
 }
 
-// busTrolleyRoutes represents a directory for all trolley routes.
+// busTrolleyRoutes represents a directory for all bus or trolley routes. It is
+// always handed to FUSE as a *busTrolleyRoutes: routeNodes and routeIDs
+// make the struct itself uncomparable, and bazil.org/fuse's Lookup
+// handler uses the returned Node as a map key.
 type busTrolleyRoutes struct {
 	routeNodes map[string]busTrolleyRoute
 	routeIDs   []string
 	inode      uint64
 }
 
-func newBusTrolleyRoutes(routes []string, isBus bool, inode uint64) (
-	r busTrolleyRoutes) {
-	r.routeNodes = make(map[string]busTrolleyRoute, len(routes))
-	r.routeIDs = routes
-	r.inode = inode
+func newBusTrolleyRoutes(client Client, routes []string, isBus bool,
+	inode uint64) *busTrolleyRoutes {
+	r := &busTrolleyRoutes{
+		routeNodes: make(map[string]busTrolleyRoute, len(routes)),
+		routeIDs:   routes,
+		inode:      inode,
+	}
 
 	for _, id := range routes {
 		inode := fs.GenerateDynamicInode(r.inode, id)
-		r.routeNodes[id] = newBusTrolleyRoute(id, inode, isBus)
+		r.routeNodes[id] = newBusTrolleyRoute(client, id, inode, isBus)
 	}
 
-	return
+	return r
 }
 
-func (r busTrolleyRoutes) Attr() fuse.Attr {
+func (r *busTrolleyRoutes) Attr() fuse.Attr {
 	return fuse.Attr{
 		Inode: r.inode,
 		Mode:  os.ModeDir | 055,
 	}
 }
 
-func (r busTrolleyRoutes) Lookup(name string, intr fs.Intr) (n fs.Node,
+func (r *busTrolleyRoutes) Lookup(name string, intr fs.Intr) (n fs.Node,
 	err fuse.Error) {
 
 	var ok bool
@@ -121,7 +179,7 @@ func (r busTrolleyRoutes) Lookup(name string, intr fs.Intr) (n fs.Node,
 	return
 }
 
-func (r busTrolleyRoutes) ReadDir(intr fs.Intr) (dirs []fuse.Dirent,
+func (r *busTrolleyRoutes) ReadDir(intr fs.Intr) (dirs []fuse.Dirent,
 	err fuse.Error) {
 
 	dirs = make([]fuse.Dirent, len(r.routeIDs))
@@ -135,37 +193,33 @@ func (r busTrolleyRoutes) ReadDir(intr fs.Intr) (dirs []fuse.Dirent,
 
 // busTrolleyRoute represents a directory for bus/trolley data.
 type busTrolleyRoute struct {
-	route        string
-	inode        uint64
-	isBus        bool
-	locationNode busTrolleyLocation
-	alertsNode   routeAlerts
+	route string
+	inode uint64
+	isBus bool
+
+	locationsNode        *formattedFile
+	locationsJSONNode    *formattedFile
+	locationsGeoJSONNode *formattedFile
+	alertsNode           *formattedFile
+	alertsJSONNode       *formattedFile
 }
 
-func newBusTrolleyRoute(route string, inode uint64,
+func newBusTrolleyRoute(client Client, route string, inode uint64,
 	isBus bool) (b busTrolleyRoute) {
-	locations := busTrolleyLocation{
-		route: route,
-		inode: fs.GenerateDynamicInode(inode, "locations"),
-	}
-
-	var routeName string
-	if isBus {
-		routeName = fmt.Sprintf("bus_route_%s", route)
-	} else {
-		routeName = fmt.Sprintf("trolley_route_%s", route)
-	}
-
-	alerts := routeAlerts{
-		route: routeName,
-		inode: fs.GenerateDynamicInode(inode, "alerts"),
-	}
-
 	b.route = route
 	b.inode = inode
 	b.isBus = isBus
-	b.locationNode = locations
-	b.alertsNode = alerts
+
+	b.locationsNode = newLocationsFile(client, route,
+		fs.GenerateDynamicInode(inode, "locations"))
+	b.locationsJSONNode = newLocationsJSONFile(client, route,
+		fs.GenerateDynamicInode(inode, "locations.json"))
+	b.locationsGeoJSONNode = newLocationsGeoJSONFile(client, route,
+		fs.GenerateDynamicInode(inode, "locations.geojson"))
+	b.alertsNode = newAlertsFile(client, route, isBus,
+		fs.GenerateDynamicInode(inode, "alerts"))
+	b.alertsJSONNode = newAlertsJSONFile(client, route, isBus,
+		fs.GenerateDynamicInode(inode, "alerts.json"))
 
 	return
 }
@@ -185,9 +239,15 @@ func (r busTrolleyRoute) Lookup(name string, intr fs.Intr) (n fs.Node,
 
 	switch name {
 	case "locations":
-		n = r.locationNode
+		n = r.locationsNode
+	case "locations.json":
+		n = r.locationsJSONNode
+	case "locations.geojson":
+		n = r.locationsGeoJSONNode
 	case "alerts":
 		n = r.alertsNode
+	case "alerts.json":
+		n = r.alertsJSONNode
 	default:
 		err = fuse.ENOENT
 	}
@@ -199,91 +259,179 @@ func (r busTrolleyRoute) Lookup(name string, intr fs.Intr) (n fs.Node,
 func (busTrolleyRoute) ReadDir(intr fs.Intr) (dirs []fuse.Dirent,
 	err fuse.Error) {
 
-	locations := fuse.Dirent{Name: "locations", Type: fuse.DT_File}
-	alerts := fuse.Dirent{Name: "alerts", Type: fuse.DT_File}
-
-	dirs = append(dirs, locations, alerts)
+	dirs = []fuse.Dirent{
+		{Name: "locations", Type: fuse.DT_File},
+		{Name: "locations.json", Type: fuse.DT_File},
+		{Name: "locations.geojson", Type: fuse.DT_File},
+		{Name: "alerts", Type: fuse.DT_File},
+		{Name: "alerts.json", Type: fuse.DT_File},
+	}
 
 	return
 }
 
-// busTrolleyLocation represents locations for buses and trolleys on a route.
-type busTrolleyLocation struct {
-	route string
-	inode uint64
+// formattedFile serves one byte-level rendering of upstream data for a
+// route. render is responsible for both fetching and formatting it.
+// Every formattedFile for the same route and data (locations or alerts)
+// calls the same Client method with the same arguments, so a
+// CachingClient-backed Client coalesces and caches the fetch instead of
+// hitting SEPTA once per sibling file read.
+//
+// render makes formattedFile uncomparable, so it must always be handed
+// to FUSE as a *formattedFile: bazil.org/fuse's Lookup handler uses the
+// returned Node as a map key, and a func field panics on hash.
+type formattedFile struct {
+	name   string
+	route  string
+	inode  uint64
+	render func(ctx context.Context) ([]byte, error)
 }
 
-// Open sets direct IO on and returns the current busTrolleyLocation.
-func (v busTrolleyLocation) Open(req *fuse.OpenRequest,
-	resp *fuse.OpenResponse, intr fs.Intr) (h fs.Handle, err fuse.Error) {
+// Open sets direct IO on and returns the current formattedFile.
+func (f formattedFile) Open(req *fuse.OpenRequest, resp *fuse.OpenResponse,
+	intr fs.Intr) (h fs.Handle, err fuse.Error) {
 
 	resp.Flags = resp.Flags | fuse.OpenDirectIO
 
-	h = v
+	h = f
 
 	return
 }
 
-// Attr returns attributes corresponding to the bus/trolley route.
-func (v busTrolleyLocation) Attr() fuse.Attr {
-	log.Printf("getting attributes for locations on %s (%d)", v.route, v.inode)
+// Attr returns attributes corresponding to the file.
+func (f formattedFile) Attr() fuse.Attr {
+	log.Printf("getting attributes for %s on %s (%d)", f.name, f.route,
+		f.inode)
+
 	return fuse.Attr{
-		Inode: v.inode,
+		Inode: f.inode,
 		Mode:  0444,
 	}
 }
 
-// ReadAll connects to the SEPTA busTrolleyRoute API and returns the status of
-// all vehicles on the current route.
-func (v busTrolleyLocation) ReadAll(intr fs.Intr) (b []byte, err fuse.Error) {
-	log.Printf("reading all for route %s", v.route)
-
-	var ret []BusTrolley
-	if ret, err = client.TransitView(v.route); err != nil {
-		return
-	}
+// ReadAll fetches and formats the current upstream data for the file.
+func (f formattedFile) ReadAll(intr fs.Intr) (b []byte, err fuse.Error) {
+	ctx, cancel := ctxFromIntr(intr)
+	defer cancel()
 
-	for _, bt := range ret {
-		btBytes := []byte(bt.String())
-		btBytes = append(btBytes, '\n')
-		b = append(b, btBytes...)
+	var e error
+	if b, e = f.render(ctx); e != nil {
+		err = e
 	}
 
 	return
 }
 
-// routeAlerts represents alerts for a route on any mode.
-type routeAlerts struct {
-	route string
-	inode uint64
+// newLocationsFile returns a formattedFile rendering route's locations as
+// human-readable text.
+func newLocationsFile(client Client, route string, inode uint64) *formattedFile {
+	return &formattedFile{
+		name:  "locations",
+		route: route,
+		inode: inode,
+		render: func(ctx context.Context) ([]byte, error) {
+			bts, err := client.TransitViewCtx(ctx, route)
+			if err != nil {
+				return nil, err
+			}
+
+			return renderBusTrolleyText(bts), nil
+		},
+	}
 }
 
-// Open sets direct IO on and returns the current routeAlerts.
-func (r routeAlerts) Open(req *fuse.OpenRequest,
-	resp *fuse.OpenResponse, intr fs.Intr) (h fs.Handle, err fuse.Error) {
-
-	resp.Flags = resp.Flags | fuse.OpenDirectIO
+// newLocationsJSONFile returns a formattedFile rendering route's
+// locations as a JSON array of BusTrolley.
+func newLocationsJSONFile(client Client, route string,
+	inode uint64) *formattedFile {
+	return &formattedFile{
+		name:  "locations.json",
+		route: route,
+		inode: inode,
+		render: func(ctx context.Context) ([]byte, error) {
+			bts, err := client.TransitViewCtx(ctx, route)
+			if err != nil {
+				return nil, err
+			}
+
+			return json.Marshal(bts)
+		},
+	}
+}
 
-	h = r
+// newLocationsGeoJSONFile returns a formattedFile rendering route's
+// locations as a GeoJSON FeatureCollection of Point features.
+func newLocationsGeoJSONFile(client Client, route string,
+	inode uint64) *formattedFile {
+	return &formattedFile{
+		name:  "locations.geojson",
+		route: route,
+		inode: inode,
+		render: func(ctx context.Context) ([]byte, error) {
+			bts, err := client.TransitViewCtx(ctx, route)
+			if err != nil {
+				return nil, err
+			}
+
+			return renderBusTrolleyGeoJSON(bts)
+		},
+	}
+}
 
-	return
+// newAlertsFile returns a formattedFile rendering route's alerts as
+// human-readable text.
+func newAlertsFile(client Client, route string, isBus bool,
+	inode uint64) *formattedFile {
+	return &formattedFile{
+		name:  "alerts",
+		route: route,
+		inode: inode,
+		render: func(ctx context.Context) ([]byte, error) {
+			rts, err := client.RouteAlertsCtx(ctx, route, isBus)
+			if err != nil {
+				return nil, err
+			}
+
+			return renderRouteAlertsText(rts), nil
+		},
+	}
 }
 
-// Attr returns attributes corresponding to the route.
-func (r routeAlerts) Attr() fuse.Attr {
-	log.Printf("getting attributes for alerts on %s (%d)", r.route, r.inode)
-	return fuse.Attr{
-		Inode: r.inode,
-		Mode:  0444,
+// newAlertsJSONFile returns a formattedFile rendering route's alerts as
+// JSON, preserving the original HTML alongside stripped plain-text
+// fields.
+func newAlertsJSONFile(client Client, route string, isBus bool,
+	inode uint64) *formattedFile {
+	return &formattedFile{
+		name:  "alerts.json",
+		route: route,
+		inode: inode,
+		render: func(ctx context.Context) ([]byte, error) {
+			rts, err := client.RouteAlertsCtx(ctx, route, isBus)
+			if err != nil {
+				return nil, err
+			}
+
+			return renderRouteAlertsJSON(rts)
+		},
 	}
 }
 
-func (r routeAlerts) ReadAll(intr fs.Intr) (b []byte, err fuse.Error) {
-	var rts []RouteAlert
-	if rts, err = client.RouteAlerts(r.route); err != nil {
-		return
+// renderBusTrolleyText renders bts as human-readable text, one vehicle
+// per paragraph.
+func renderBusTrolleyText(bts []BusTrolley) (b []byte) {
+	for _, bt := range bts {
+		btBytes := []byte(bt.String())
+		btBytes = append(btBytes, '\n')
+		b = append(b, btBytes...)
 	}
 
+	return
+}
+
+// renderRouteAlertsText renders rts as human-readable text, grouped into
+// a current-alerts section followed by an advisories section.
+func renderRouteAlertsText(rts []RouteAlert) (b []byte) {
 	currentHeader := "CURRENT ALERTS:\n\n"
 	advisoryHeader := "ADVISORIES:\n\n"
 
@@ -307,3 +455,30 @@ func (r routeAlerts) ReadAll(intr fs.Intr) (b []byte, err fuse.Error) {
 
 	return
 }
+
+// alertJSON is the alerts.json representation of a RouteAlert: the
+// original HTML messages as SEPTA delivers them, plus their stripped
+// plain-text equivalents for callers that don't want to handle markup.
+type alertJSON struct {
+	RouteName                string `json:"route_name"`
+	CurrentMessage           string `json:"current_message"`
+	AdvisoryMessage          string `json:"advisory_message"`
+	CurrentMessagePlainText  string `json:"current_message_plain_text"`
+	AdvisoryMessagePlainText string `json:"advisory_message_plain_text"`
+}
+
+// renderRouteAlertsJSON renders rts as a JSON array of alertJSON.
+func renderRouteAlertsJSON(rts []RouteAlert) ([]byte, error) {
+	out := make([]alertJSON, len(rts))
+	for i, rt := range rts {
+		out[i] = alertJSON{
+			RouteName:                rt.RouteName,
+			CurrentMessage:           rt.CurrentMessageHTML,
+			AdvisoryMessage:          rt.AdvisoryMessageHTML,
+			CurrentMessagePlainText:  rt.CurrentMessage,
+			AdvisoryMessagePlainText: rt.AdvisoryMessage,
+		}
+	}
+
+	return json.Marshal(out)
+}
@@ -0,0 +1,50 @@
+package septa
+
+import "encoding/json"
+
+// geoJSONFeatureCollection is the minimal RFC 7946 FeatureCollection
+// shape septafs needs to describe vehicle positions.
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+// geoJSONFeature is a single RFC 7946 Feature wrapping a Point geometry.
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONPoint           `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// geoJSONPoint is an RFC 7946 Point geometry, longitude before latitude
+// as the spec requires.
+type geoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// renderBusTrolleyGeoJSON renders bts as a GeoJSON FeatureCollection of
+// Point features, with vehicle metadata in each feature's properties.
+func renderBusTrolleyGeoJSON(bts []BusTrolley) ([]byte, error) {
+	fc := geoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]geoJSONFeature, len(bts)),
+	}
+
+	for i, bt := range bts {
+		fc.Features[i] = geoJSONFeature{
+			Type: "Feature",
+			Geometry: geoJSONPoint{
+				Type:        "Point",
+				Coordinates: [2]float64{bt.Lng, bt.Lat},
+			},
+			Properties: map[string]interface{}{
+				"direction":   bt.Direction,
+				"destination": bt.Destination,
+				"last_read":   bt.LastRead,
+			},
+		}
+	}
+
+	return json.Marshal(fc)
+}
@@ -0,0 +1,55 @@
+package septa
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRenderBusTrolleyGeoJSON(t *testing.T) {
+	bts := []BusTrolley{
+		{
+			Lat:         39.9526,
+			Lng:         -75.1652,
+			Direction:   "NW",
+			Destination: "Frankford Transportation Center",
+			LastRead:    2,
+		},
+	}
+
+	b, err := renderBusTrolleyGeoJSON(bts)
+	if err != nil {
+		t.Fatalf("renderBusTrolleyGeoJSON: %v", err)
+	}
+
+	var fc geoJSONFeatureCollection
+	if err := json.Unmarshal(b, &fc); err != nil {
+		t.Fatalf("unmarshaling rendered GeoJSON: %v", err)
+	}
+
+	if fc.Type != "FeatureCollection" {
+		t.Errorf("Type = %q, want %q", fc.Type, "FeatureCollection")
+	}
+	if len(fc.Features) != 1 {
+		t.Fatalf("got %d features, want 1", len(fc.Features))
+	}
+
+	f := fc.Features[0]
+	if f.Geometry.Type != "Point" {
+		t.Errorf("Geometry.Type = %q, want %q", f.Geometry.Type, "Point")
+	}
+
+	wantCoords := [2]float64{-75.1652, 39.9526}
+	if f.Geometry.Coordinates != wantCoords {
+		t.Errorf("Coordinates = %v, want %v (lng before lat)",
+			f.Geometry.Coordinates, wantCoords)
+	}
+
+	if f.Properties["direction"] != "NW" {
+		t.Errorf("properties.direction = %v, want %q",
+			f.Properties["direction"], "NW")
+	}
+	if f.Properties["destination"] != "Frankford Transportation Center" {
+		t.Errorf("properties.destination = %v, want %q",
+			f.Properties["destination"], "Frankford Transportation Center")
+	}
+}
@@ -0,0 +1,207 @@
+package septa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/MobilityData/gtfs-realtime-bindings/golang/gtfs"
+	"github.com/golang/protobuf/proto"
+)
+
+// errGTFSRealtimeUnsupported is returned by GTFSRealtimeClient methods that
+// have no equivalent in a VehiclePositions/Alerts feed.
+var errGTFSRealtimeUnsupported = errors.New(
+	"septa: not supported by a GTFS-Realtime feed")
+
+// GTFSRealtimeClient implements Client against a standard GTFS-Realtime
+// VehiclePositions feed and a GTFS-Realtime Alerts feed, such as the ones
+// SEPTA publishes alongside its hackathon API.
+type GTFSRealtimeClient struct {
+	VehiclePositionsURL string
+	AlertsURL           string
+}
+
+// NewGTFSRealtimeClient returns a GTFSRealtimeClient that reads vehicle
+// positions and alerts from the given feed URLs.
+func NewGTFSRealtimeClient(vehiclePositionsURL,
+	alertsURL string) GTFSRealtimeClient {
+	return GTFSRealtimeClient{
+		VehiclePositionsURL: vehiclePositionsURL,
+		AlertsURL:           alertsURL,
+	}
+}
+
+var _ Client = GTFSRealtimeClient{}
+
+func (c GTFSRealtimeClient) fetchFeed(ctx context.Context, url string) (
+	feed *gtfs.FeedMessage, err error) {
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, "GET", url, nil); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var body []byte
+	if body, err = ioutil.ReadAll(resp.Body); err != nil {
+		return
+	}
+
+	feed = &gtfs.FeedMessage{}
+	err = proto.Unmarshal(body, feed)
+
+	return
+}
+
+// TransitView returns the current transit status for the given route.
+func (c GTFSRealtimeClient) TransitView(route string) ([]BusTrolley, error) {
+	return c.TransitViewCtx(context.Background(), route)
+}
+
+// TransitViewCtx is TransitView with a caller-supplied context. It filters
+// VehiclePositions entities whose trip belongs to route.
+func (c GTFSRealtimeClient) TransitViewCtx(ctx context.Context,
+	route string) (bts []BusTrolley, err error) {
+	var feed *gtfs.FeedMessage
+	if feed, err = c.fetchFeed(ctx, c.VehiclePositionsURL); err != nil {
+		return
+	}
+
+	for _, entity := range feed.GetEntity() {
+		vp := entity.GetVehicle()
+		if vp == nil || vp.GetTrip().GetRouteId() != route {
+			continue
+		}
+
+		pos := vp.GetPosition()
+
+		bt := BusTrolley{
+			Lat:         float64(pos.GetLatitude()),
+			Lng:         float64(pos.GetLongitude()),
+			Direction:   fmt.Sprintf("%.0f", pos.GetBearing()),
+			Destination: vp.GetVehicle().GetLabel(),
+		}
+
+		if ts := vp.GetTimestamp(); ts > 0 {
+			bt.LastRead = int(time.Now().Sub(time.Unix(int64(ts), 0)).Minutes())
+		}
+
+		bts = append(bts, bt)
+	}
+
+	return
+}
+
+// RouteAlerts returns alerts for the given route.
+func (c GTFSRealtimeClient) RouteAlerts(route string, isBus bool) (
+	[]RouteAlert, error) {
+	return c.RouteAlertsCtx(context.Background(), route, isBus)
+}
+
+// RouteAlertsCtx is RouteAlerts with a caller-supplied context. It filters
+// Alert entities whose informed entities reference route. isBus is
+// unused: GTFS-Realtime informed entities carry the plain GTFS route id
+// regardless of vehicle kind, so there's no prefix to disambiguate.
+func (c GTFSRealtimeClient) RouteAlertsCtx(ctx context.Context,
+	route string, isBus bool) (rts []RouteAlert, err error) {
+	var feed *gtfs.FeedMessage
+	if feed, err = c.fetchFeed(ctx, c.AlertsURL); err != nil {
+		return
+	}
+
+	for _, entity := range feed.GetEntity() {
+		alert := entity.GetAlert()
+		if alert == nil || !informsRoute(alert, route) {
+			continue
+		}
+
+		current := firstTranslation(alert.GetHeaderText())
+		advisory := firstTranslation(alert.GetDescriptionText())
+
+		rts = append(rts, RouteAlert{
+			RouteName:       route,
+			CurrentMessage:  current,
+			AdvisoryMessage: advisory,
+
+			// GTFS-Realtime alert text has no markup to begin with, so
+			// there's nothing for the HTML fields to preserve beyond
+			// what's already plain.
+			CurrentMessageHTML:  current,
+			AdvisoryMessageHTML: advisory,
+		})
+	}
+
+	return
+}
+
+func informsRoute(alert *gtfs.Alert, route string) bool {
+	for _, ie := range alert.GetInformedEntity() {
+		if ie.GetRouteId() == route {
+			return true
+		}
+	}
+
+	return false
+}
+
+func firstTranslation(t *gtfs.TranslatedString) string {
+	if t == nil {
+		return ""
+	}
+
+	for _, tr := range t.GetTranslation() {
+		return tr.GetText()
+	}
+
+	return ""
+}
+
+// TrainView is not supported by a VehiclePositions/Alerts feed.
+func (c GTFSRealtimeClient) TrainView() ([]Train, error) {
+	return nil, errGTFSRealtimeUnsupported
+}
+
+// TrainViewCtx is not supported by a VehiclePositions/Alerts feed.
+func (c GTFSRealtimeClient) TrainViewCtx(ctx context.Context) (
+	[]Train, error) {
+	return nil, errGTFSRealtimeUnsupported
+}
+
+// Arrivals is not supported by a VehiclePositions/Alerts feed.
+func (c GTFSRealtimeClient) Arrivals(stopID string, results int) (
+	[]Arrival, error) {
+	return nil, errGTFSRealtimeUnsupported
+}
+
+// ArrivalsCtx is not supported by a VehiclePositions/Alerts feed.
+func (c GTFSRealtimeClient) ArrivalsCtx(ctx context.Context, stopID string,
+	results int) ([]Arrival, error) {
+	return nil, errGTFSRealtimeUnsupported
+}
+
+// NextToArrive is not supported by a VehiclePositions/Alerts feed.
+func (c GTFSRealtimeClient) NextToArrive(orig, dest string, results int) (
+	[]NextToArrive, error) {
+	return nil, errGTFSRealtimeUnsupported
+}
+
+// NextToArriveCtx is not supported by a VehiclePositions/Alerts feed.
+func (c GTFSRealtimeClient) NextToArriveCtx(ctx context.Context, orig,
+	dest string, results int) ([]NextToArrive, error) {
+	return nil, errGTFSRealtimeUnsupported
+}
+
+// Stops returns the list of known transit stops, loaded once from the
+// bundled GTFS stops.txt. Static stop data isn't feed-specific, so it's
+// available here even though the realtime endpoints aren't.
+func (c GTFSRealtimeClient) Stops() ([]Stop, error) {
+	return loadStops()
+}
@@ -0,0 +1,126 @@
+package septa
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MobilityData/gtfs-realtime-bindings/golang/gtfs"
+	"github.com/golang/protobuf/proto"
+)
+
+func serveFeed(t *testing.T, feed *gtfs.FeedMessage) string {
+	t.Helper()
+
+	if feed.Header == nil {
+		feed.Header = &gtfs.FeedHeader{
+			GtfsRealtimeVersion: proto.String("2.0"),
+		}
+	}
+
+	body, err := proto.Marshal(feed)
+	if err != nil {
+		t.Fatalf("marshaling fixture feed: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write(body)
+		}))
+	t.Cleanup(srv.Close)
+
+	return srv.URL
+}
+
+func TestGTFSRealtimeClientTransitViewCtxFormatsBearing(t *testing.T) {
+	feed := &gtfs.FeedMessage{
+		Entity: []*gtfs.FeedEntity{
+			{
+				Id: proto.String("1"),
+				Vehicle: &gtfs.VehiclePosition{
+					Trip: &gtfs.TripDescriptor{RouteId: proto.String("42")},
+					Position: &gtfs.Position{
+						Latitude:  proto.Float32(39.9526),
+						Longitude: proto.Float32(-75.1652),
+						Bearing:   proto.Float32(95),
+					},
+					Vehicle: &gtfs.VehicleDescriptor{
+						Label: proto.String("4201"),
+					},
+				},
+			},
+			{
+				Id: proto.String("2"),
+				Vehicle: &gtfs.VehiclePosition{
+					Trip: &gtfs.TripDescriptor{RouteId: proto.String("23")},
+				},
+			},
+		},
+	}
+
+	c := GTFSRealtimeClient{VehiclePositionsURL: serveFeed(t, feed)}
+
+	bts, err := c.TransitViewCtx(context.Background(), "42")
+	if err != nil {
+		t.Fatalf("TransitViewCtx: %v", err)
+	}
+
+	if len(bts) != 1 {
+		t.Fatalf("got %d vehicles, want 1 (route 23 should be filtered out)",
+			len(bts))
+	}
+
+	// Regression: Position.GetBearing() returns a float32, and
+	// formatting it with %d used to produce "%!d(float32=95)" instead
+	// of "95".
+	if bts[0].Direction != "95" {
+		t.Errorf("Direction = %q, want %q", bts[0].Direction, "95")
+	}
+}
+
+func TestGTFSRealtimeClientRouteAlertsCtxFiltersOnPlainRouteID(t *testing.T) {
+	feed := &gtfs.FeedMessage{
+		Entity: []*gtfs.FeedEntity{
+			{
+				Id: proto.String("alert-1"),
+				Alert: &gtfs.Alert{
+					InformedEntity: []*gtfs.EntitySelector{
+						{RouteId: proto.String("42")},
+					},
+					HeaderText: &gtfs.TranslatedString{
+						Translation: []*gtfs.TranslatedString_Translation{
+							{Text: proto.String("Route 42 detour")},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	c := GTFSRealtimeClient{AlertsURL: serveFeed(t, feed)}
+
+	// The FS layer is expected to pass the plain GTFS route id, the
+	// same one used for TransitView -- never a hackathon-style
+	// "bus_route_42" key, which GTFS-Realtime informed entities never
+	// carry.
+	rts, err := c.RouteAlertsCtx(context.Background(), "42", true)
+	if err != nil {
+		t.Fatalf("RouteAlertsCtx(42): %v", err)
+	}
+	if len(rts) != 1 {
+		t.Fatalf("got %d alerts for route 42, want 1", len(rts))
+	}
+	if rts[0].CurrentMessage != "Route 42 detour" {
+		t.Errorf("CurrentMessage = %q, want %q", rts[0].CurrentMessage,
+			"Route 42 detour")
+	}
+
+	rts, err = c.RouteAlertsCtx(context.Background(), "bus_route_42", true)
+	if err != nil {
+		t.Fatalf("RouteAlertsCtx(bus_route_42): %v", err)
+	}
+	if len(rts) != 0 {
+		t.Errorf("got %d alerts for a hackathon-style key, want 0", len(rts))
+	}
+}
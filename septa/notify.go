@@ -0,0 +1,186 @@
+package septa
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"log"
+	"time"
+
+	"bazil.org/fuse/fs"
+)
+
+// DefaultNotifyInterval is how often the notifier polls upstream for
+// changes to invalidate.
+const DefaultNotifyInterval = 30 * time.Second
+
+// notifyMaxBackoff caps how far a route's next poll is pushed out after
+// consecutive upstream errors.
+const notifyMaxBackoff = 10 * time.Minute
+
+// notifier polls a Client for changes to every known route's locations
+// and alerts and invalidates the kernel's cache for the affected FUSE
+// nodes, so a reader tailing a file sees fresh data without waiting on
+// the kernel's own cache to expire.
+type notifier struct {
+	client Client
+	server *fs.Server
+	routes []*notifyRoute
+}
+
+// notifyRoute tracks the polling state for a single route's locations and
+// alerts, along with every sibling formattedFile node that renders each
+// (text, JSON, GeoJSON) so a single upstream change invalidates all of
+// them.
+type notifyRoute struct {
+	route          string
+	isBus          bool
+	locationsNodes []fs.Node
+
+	alertsNodes []fs.Node
+
+	locationsHash     [sha256.Size]byte
+	locationsBackoff  time.Duration
+	locationsNextPoll time.Time
+
+	alertsHash     [sha256.Size]byte
+	alertsBackoff  time.Duration
+	alertsNextPoll time.Time
+}
+
+// newNotifier returns a notifier watching every bus and trolley route
+// known to root.
+func newNotifier(client Client, server *fs.Server, root rootDir) *notifier {
+	n := &notifier{client: client, server: server}
+
+	for _, routes := range []*busTrolleyRoutes{root.trolleyNode, root.busNode} {
+		for _, id := range routes.routeIDs {
+			route := routes.routeNodes[id]
+			n.routes = append(n.routes, &notifyRoute{
+				route: route.route,
+				isBus: route.isBus,
+				locationsNodes: []fs.Node{
+					route.locationsNode,
+					route.locationsJSONNode,
+					route.locationsGeoJSONNode,
+				},
+				alertsNodes: []fs.Node{
+					route.alertsNode,
+					route.alertsJSONNode,
+				},
+			})
+		}
+	}
+
+	return n
+}
+
+// run polls every known route every interval until ctx is done.
+func (n *notifier) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for _, rt := range n.routes {
+				n.pollLocations(ctx, rt)
+				n.pollAlerts(ctx, rt)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// pollLocations refetches locations for rt's route, invalidating the
+// kernel's cache of every node rendering it if the content changed since
+// the last poll.
+func (n *notifier) pollLocations(ctx context.Context, rt *notifyRoute) {
+	if time.Now().Before(rt.locationsNextPoll) {
+		return
+	}
+
+	bts, err := n.client.TransitViewCtx(ctx, rt.route)
+	if err != nil {
+		rt.locationsBackoff = nextNotifyBackoff(rt.locationsBackoff)
+		rt.locationsNextPoll = time.Now().Add(rt.locationsBackoff)
+		log.Printf("notifier: polling locations for %s: %v", rt.route, err)
+
+		return
+	}
+
+	rt.locationsBackoff = 0
+
+	hash := hashJSON(bts)
+	if hash == rt.locationsHash {
+		return
+	}
+	rt.locationsHash = hash
+
+	for _, node := range rt.locationsNodes {
+		if err = n.server.InvalidateNodeData(node); err != nil {
+			log.Printf("notifier: invalidating locations for %s: %v",
+				rt.route, err)
+		}
+	}
+}
+
+// pollAlerts refetches alerts for rt's route, invalidating the kernel's
+// cache of every node rendering it if the content changed since the last
+// poll.
+func (n *notifier) pollAlerts(ctx context.Context, rt *notifyRoute) {
+	if time.Now().Before(rt.alertsNextPoll) {
+		return
+	}
+
+	rts, err := n.client.RouteAlertsCtx(ctx, rt.route, rt.isBus)
+	if err != nil {
+		rt.alertsBackoff = nextNotifyBackoff(rt.alertsBackoff)
+		rt.alertsNextPoll = time.Now().Add(rt.alertsBackoff)
+		log.Printf("notifier: polling alerts for %s: %v", rt.route, err)
+
+		return
+	}
+
+	rt.alertsBackoff = 0
+
+	hash := hashJSON(rts)
+	if hash == rt.alertsHash {
+		return
+	}
+	rt.alertsHash = hash
+
+	for _, node := range rt.alertsNodes {
+		if err = n.server.InvalidateNodeData(node); err != nil {
+			log.Printf("notifier: invalidating alerts for %s: %v",
+				rt.route, err)
+		}
+	}
+}
+
+// nextNotifyBackoff doubles cur, starting from one second, capped at
+// notifyMaxBackoff.
+func nextNotifyBackoff(cur time.Duration) time.Duration {
+	if cur == 0 {
+		return time.Second
+	}
+
+	next := cur * 2
+	if next > notifyMaxBackoff {
+		next = notifyMaxBackoff
+	}
+
+	return next
+}
+
+// hashJSON returns the SHA-256 hash of v's JSON encoding, used to detect
+// whether upstream content changed between polls.
+func hashJSON(v interface{}) [sha256.Size]byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("notifier: marshaling for hash: %v", err)
+	}
+
+	return sha256.Sum256(b)
+}
@@ -0,0 +1,38 @@
+package septa
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextNotifyBackoff(t *testing.T) {
+	tests := []struct {
+		cur  time.Duration
+		want time.Duration
+	}{
+		{0, time.Second},
+		{time.Second, 2 * time.Second},
+		{notifyMaxBackoff, notifyMaxBackoff},
+		{notifyMaxBackoff / 2, notifyMaxBackoff},
+	}
+
+	for _, tt := range tests {
+		if got := nextNotifyBackoff(tt.cur); got != tt.want {
+			t.Errorf("nextNotifyBackoff(%v) = %v, want %v", tt.cur, got,
+				tt.want)
+		}
+	}
+}
+
+func TestHashJSONDetectsChanges(t *testing.T) {
+	a := []BusTrolley{{Lat: 39.95, Lng: -75.16}}
+	b := []BusTrolley{{Lat: 39.95, Lng: -75.16}}
+	c := []BusTrolley{{Lat: 40.00, Lng: -75.16}}
+
+	if hashJSON(a) != hashJSON(b) {
+		t.Error("hashJSON differed for identical content")
+	}
+	if hashJSON(a) == hashJSON(c) {
+		t.Error("hashJSON matched for different content")
+	}
+}
@@ -0,0 +1,174 @@
+package septa
+
+import (
+	"log"
+	"os"
+
+	"bazil.org/fuse"
+	"bazil.org/fuse/fs"
+)
+
+// ntaResults is how many results septafs requests from NextToArrive.
+const ntaResults = 5
+
+// ntaDir represents the nta/ directory, with one subdirectory per known
+// origin station. It is always handed to FUSE as a *ntaDir: names makes
+// the struct itself uncomparable, and bazil.org/fuse's Lookup handler
+// uses the returned Node as a map key.
+type ntaDir struct {
+	client Client
+	names  []string
+	inode  uint64
+}
+
+func newNtaDir(client Client, inode uint64) *ntaDir {
+	stops, err := loadStops()
+	if err != nil {
+		log.Printf("failed to load stops for nta: %v", err)
+	}
+
+	names := make([]string, len(stops))
+	for i, s := range stops {
+		names[i] = s.Name
+	}
+
+	return &ntaDir{client: client, names: names, inode: inode}
+}
+
+func (*ntaDir) Attr() fuse.Attr {
+	return fuse.Attr{
+		Inode: 5,
+		Mode:  os.ModeDir | 0555,
+	}
+}
+
+func (n *ntaDir) ReadDir(intr fs.Intr) (dirs []fuse.Dirent, err fuse.Error) {
+	dirs = make([]fuse.Dirent, len(n.names))
+
+	for i, name := range n.names {
+		dirs[i] = fuse.Dirent{Name: name, Type: fuse.DT_Dir}
+	}
+
+	return
+}
+
+// Lookup returns a directory for the given origin station.
+func (n *ntaDir) Lookup(name string, intr fs.Intr) (node fs.Node,
+	err fuse.Error) {
+	for _, s := range n.names {
+		if s == name {
+			node = &ntaOrig{
+				client: n.client,
+				orig:   name,
+				names:  n.names,
+				inode:  fs.GenerateDynamicInode(n.inode, name),
+			}
+
+			return
+		}
+	}
+
+	err = fuse.ENOENT
+
+	return
+}
+
+// ntaOrig represents the directory for a single origin station, with one
+// file per possible destination station. It is always handed to FUSE as
+// a *ntaOrig: names makes the struct itself uncomparable, and
+// bazil.org/fuse's Lookup handler uses the returned Node as a map key.
+type ntaOrig struct {
+	client Client
+	orig   string
+	names  []string
+	inode  uint64
+}
+
+func (o *ntaOrig) Attr() fuse.Attr {
+	return fuse.Attr{
+		Inode: o.inode,
+		Mode:  os.ModeDir | 0555,
+	}
+}
+
+func (o *ntaOrig) ReadDir(intr fs.Intr) (dirs []fuse.Dirent, err fuse.Error) {
+	dirs = make([]fuse.Dirent, len(o.names))
+
+	for i, name := range o.names {
+		dirs[i] = fuse.Dirent{Name: name, Type: fuse.DT_File}
+	}
+
+	return
+}
+
+// Lookup returns a file with next-to-arrive results between o.orig and
+// the given destination station.
+func (o *ntaOrig) Lookup(name string, intr fs.Intr) (node fs.Node,
+	err fuse.Error) {
+	for _, s := range o.names {
+		if s == name {
+			node = ntaResult{
+				client: o.client,
+				orig:   o.orig,
+				dest:   name,
+				inode:  fs.GenerateDynamicInode(o.inode, name),
+			}
+
+			return
+		}
+	}
+
+	err = fuse.ENOENT
+
+	return
+}
+
+// ntaResult represents next-to-arrive results between two stations.
+type ntaResult struct {
+	client Client
+	orig   string
+	dest   string
+	inode  uint64
+}
+
+// Open sets direct IO on and returns the current ntaResult.
+func (r ntaResult) Open(req *fuse.OpenRequest, resp *fuse.OpenResponse,
+	intr fs.Intr) (h fs.Handle, err fuse.Error) {
+	resp.Flags = resp.Flags | fuse.OpenDirectIO
+
+	h = r
+
+	return
+}
+
+// Attr returns attributes corresponding to the origin/destination pair.
+func (r ntaResult) Attr() fuse.Attr {
+	log.Printf("getting attributes for nta %s -> %s (%d)", r.orig, r.dest,
+		r.inode)
+
+	return fuse.Attr{
+		Inode: r.inode,
+		Mode:  0444,
+	}
+}
+
+// ReadAll connects to the SEPTA NextToArrive API and returns the next
+// vehicles scheduled to arrive at r.dest from r.orig.
+func (r ntaResult) ReadAll(intr fs.Intr) (b []byte, err fuse.Error) {
+	ctx, cancel := ctxFromIntr(intr)
+	defer cancel()
+
+	var ret []NextToArrive
+	if ret, err = r.client.NextToArriveCtx(ctx, r.orig, r.dest,
+		ntaResults); err != nil {
+		return
+	}
+
+	for _, nta := range ret {
+		ntaBytes := []byte(nta.String())
+		ntaBytes = append(ntaBytes, '\n')
+		b = append(b, ntaBytes...)
+	}
+
+	return
+}
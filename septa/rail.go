@@ -0,0 +1,159 @@
+package septa
+
+import (
+	"log"
+	"os"
+
+	"bazil.org/fuse"
+	"bazil.org/fuse/fs"
+)
+
+var railLineIDs = []string{"AIR", "CHE", "CHW", "FOX", "LAN", "DOY", "MED",
+	"NOR", "PAO", "TRE", "WAR", "WTR", "WIL"}
+
+// railLines represents the rail/ directory, with one subdirectory per
+// Regional Rail line. It is always handed to FUSE as a *railLines:
+// lineNodes and lineIDs make the struct itself uncomparable, and
+// bazil.org/fuse's Lookup handler uses the returned Node as a map key.
+type railLines struct {
+	lineNodes map[string]railLine
+	lineIDs   []string
+}
+
+func newRailLines(client Client) *railLines {
+	r := &railLines{
+		lineNodes: make(map[string]railLine, len(railLineIDs)),
+		lineIDs:   railLineIDs,
+	}
+
+	for _, id := range railLineIDs {
+		inode := fs.GenerateDynamicInode(4, id)
+		r.lineNodes[id] = newRailLine(client, id, inode)
+	}
+
+	return r
+}
+
+func (*railLines) Attr() fuse.Attr {
+	return fuse.Attr{
+		Inode: 4,
+		Mode:  os.ModeDir | 0555,
+	}
+}
+
+func (r *railLines) Lookup(name string, intr fs.Intr) (n fs.Node,
+	err fuse.Error) {
+	var ok bool
+	if n, ok = r.lineNodes[name]; !ok {
+		err = fuse.ENOENT
+	}
+
+	return
+}
+
+func (r *railLines) ReadDir(intr fs.Intr) (dirs []fuse.Dirent,
+	err fuse.Error) {
+	dirs = make([]fuse.Dirent, len(r.lineIDs))
+
+	for i, id := range r.lineIDs {
+		dirs[i] = fuse.Dirent{Name: id, Type: fuse.DT_Dir}
+	}
+
+	return
+}
+
+// railLine represents a directory for a single Regional Rail line.
+type railLine struct {
+	line       string
+	inode      uint64
+	trainsNode trains
+}
+
+func newRailLine(client Client, line string, inode uint64) railLine {
+	return railLine{
+		line:  line,
+		inode: inode,
+		trainsNode: trains{
+			client: client,
+			line:   line,
+			inode:  fs.GenerateDynamicInode(inode, "trains"),
+		},
+	}
+}
+
+func (r railLine) Attr() fuse.Attr {
+	return fuse.Attr{
+		Inode: r.inode,
+		Mode:  os.ModeDir | 0555,
+	}
+}
+
+// Lookup returns a node for the given file under a rail line directory.
+func (r railLine) Lookup(name string, intr fs.Intr) (n fs.Node,
+	err fuse.Error) {
+	switch name {
+	case "trains":
+		n = r.trainsNode
+	default:
+		err = fuse.ENOENT
+	}
+
+	return
+}
+
+func (railLine) ReadDir(intr fs.Intr) (dirs []fuse.Dirent, err fuse.Error) {
+	dirs = []fuse.Dirent{{Name: "trains", Type: fuse.DT_File}}
+
+	return
+}
+
+// trains represents the current trains running on a Regional Rail line.
+type trains struct {
+	client Client
+	line   string
+	inode  uint64
+}
+
+// Open sets direct IO on and returns the current trains node.
+func (t trains) Open(req *fuse.OpenRequest, resp *fuse.OpenResponse,
+	intr fs.Intr) (h fs.Handle, err fuse.Error) {
+	resp.Flags = resp.Flags | fuse.OpenDirectIO
+
+	h = t
+
+	return
+}
+
+// Attr returns attributes corresponding to the rail line.
+func (t trains) Attr() fuse.Attr {
+	log.Printf("getting attributes for trains on %s (%d)", t.line, t.inode)
+
+	return fuse.Attr{
+		Inode: t.inode,
+		Mode:  0444,
+	}
+}
+
+// ReadAll connects to the SEPTA TrainView API and returns the status of
+// every train currently running on the line.
+func (t trains) ReadAll(intr fs.Intr) (b []byte, err fuse.Error) {
+	ctx, cancel := ctxFromIntr(intr)
+	defer cancel()
+
+	var ret []Train
+	if ret, err = t.client.TrainViewCtx(ctx); err != nil {
+		return
+	}
+
+	for _, tr := range ret {
+		if tr.Line != t.line {
+			continue
+		}
+
+		trBytes := []byte(tr.String())
+		trBytes = append(trBytes, '\n')
+		b = append(b, trBytes...)
+	}
+
+	return
+}
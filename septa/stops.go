@@ -0,0 +1,261 @@
+package septa
+
+import (
+	"encoding/csv"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"bazil.org/fuse"
+	"bazil.org/fuse/fs"
+)
+
+// arrivalsResults is how many results septafs requests from Arrivals.
+const arrivalsResults = 5
+
+var (
+	stopsOnce sync.Once
+	stopsList []Stop
+	stopsErr  error
+)
+
+// loadStops parses the bundled GTFS stops.txt once and caches the result
+// for subsequent callers.
+func loadStops() ([]Stop, error) {
+	stopsOnce.Do(func() {
+		stopsList, stopsErr = parseStops(stopsCSV)
+	})
+
+	return stopsList, stopsErr
+}
+
+func parseStops(data string) (stops []Stop, err error) {
+	r := csv.NewReader(strings.NewReader(data))
+
+	var header []string
+	if header, err = r.Read(); err != nil {
+		return
+	}
+
+	col := make(map[string]int, len(header))
+	for i, name := range header {
+		col[name] = i
+	}
+
+	for {
+		var rec []string
+		if rec, err = r.Read(); err == io.EOF {
+			err = nil
+			break
+		} else if err != nil {
+			return
+		}
+
+		s := Stop{
+			ID:   rec[col["stop_id"]],
+			Name: rec[col["stop_name"]],
+		}
+
+		if s.Lat, err = strconv.ParseFloat(rec[col["stop_lat"]], 64); err != nil {
+			return
+		}
+
+		if s.Lng, err = strconv.ParseFloat(rec[col["stop_lon"]], 64); err != nil {
+			return
+		}
+
+		if i, ok := col["stop_lines"]; ok && rec[i] != "" {
+			s.Lines = strings.Split(rec[i], ";")
+		}
+
+		stops = append(stops, s)
+	}
+
+	return
+}
+
+// stopsLines represents the stops/ directory, with one subdirectory per
+// Regional Rail line. It is always handed to FUSE as a *stopsLines:
+// lineNodes and lineIDs make the struct itself uncomparable, and
+// bazil.org/fuse's Lookup handler uses the returned Node as a map key.
+type stopsLines struct {
+	lineNodes map[string]*stopsLine
+	lineIDs   []string
+}
+
+func newStopsLines(client Client) *stopsLines {
+	stops, err := loadStops()
+	if err != nil {
+		log.Printf("failed to load stops for stops: %v", err)
+	}
+
+	s := &stopsLines{
+		lineNodes: make(map[string]*stopsLine, len(railLineIDs)),
+		lineIDs:   railLineIDs,
+	}
+
+	for _, id := range railLineIDs {
+		inode := fs.GenerateDynamicInode(6, id)
+		s.lineNodes[id] = newStopsLine(client, id, stops, inode)
+	}
+
+	return s
+}
+
+func (*stopsLines) Attr() fuse.Attr {
+	return fuse.Attr{
+		Inode: 6,
+		Mode:  os.ModeDir | 0555,
+	}
+}
+
+func (s *stopsLines) Lookup(name string, intr fs.Intr) (n fs.Node,
+	err fuse.Error) {
+	var ok bool
+	if n, ok = s.lineNodes[name]; !ok {
+		err = fuse.ENOENT
+	}
+
+	return
+}
+
+func (s *stopsLines) ReadDir(intr fs.Intr) (dirs []fuse.Dirent,
+	err fuse.Error) {
+	dirs = make([]fuse.Dirent, len(s.lineIDs))
+
+	for i, id := range s.lineIDs {
+		dirs[i] = fuse.Dirent{Name: id, Type: fuse.DT_Dir}
+	}
+
+	return
+}
+
+// stopsLine represents a directory for a single Regional Rail line, with
+// one file per known stop. It is always handed to FUSE as a *stopsLine:
+// stopNodes and stopIDs make the struct itself uncomparable, and
+// bazil.org/fuse's Lookup handler uses the returned Node as a map key.
+type stopsLine struct {
+	client    Client
+	stopNodes map[string]stopsStop
+	stopIDs   []string
+	inode     uint64
+}
+
+// newStopsLine returns a stopsLine exposing only the stops served by
+// line, mirroring how trains.ReadAll keeps only the trains on its line.
+func newStopsLine(client Client, line string, stops []Stop,
+	inode uint64) *stopsLine {
+	stopNodes := make(map[string]stopsStop)
+	var stopIDs []string
+
+	for _, st := range stops {
+		if !stopServesLine(st, line) {
+			continue
+		}
+
+		stopIDs = append(stopIDs, st.ID)
+		stopNodes[st.ID] = stopsStop{
+			client: client,
+			stopID: st.ID,
+			inode:  fs.GenerateDynamicInode(inode, st.ID),
+		}
+	}
+
+	return &stopsLine{
+		client:    client,
+		stopNodes: stopNodes,
+		stopIDs:   stopIDs,
+		inode:     inode,
+	}
+}
+
+// stopServesLine reports whether st is served by line.
+func stopServesLine(st Stop, line string) bool {
+	for _, l := range st.Lines {
+		if l == line {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *stopsLine) Attr() fuse.Attr {
+	return fuse.Attr{
+		Inode: l.inode,
+		Mode:  os.ModeDir | 0555,
+	}
+}
+
+// Lookup returns a file for the given stop ID.
+func (l *stopsLine) Lookup(name string, intr fs.Intr) (n fs.Node,
+	err fuse.Error) {
+	var ok bool
+	if n, ok = l.stopNodes[name]; !ok {
+		err = fuse.ENOENT
+	}
+
+	return
+}
+
+func (l *stopsLine) ReadDir(intr fs.Intr) (dirs []fuse.Dirent,
+	err fuse.Error) {
+	dirs = make([]fuse.Dirent, len(l.stopIDs))
+
+	for i, id := range l.stopIDs {
+		dirs[i] = fuse.Dirent{Name: id, Type: fuse.DT_File}
+	}
+
+	return
+}
+
+// stopsStop represents scheduled arrivals at a single stop.
+type stopsStop struct {
+	client Client
+	stopID string
+	inode  uint64
+}
+
+// Open sets direct IO on and returns the current stopsStop.
+func (s stopsStop) Open(req *fuse.OpenRequest, resp *fuse.OpenResponse,
+	intr fs.Intr) (h fs.Handle, err fuse.Error) {
+	resp.Flags = resp.Flags | fuse.OpenDirectIO
+
+	h = s
+
+	return
+}
+
+// Attr returns attributes corresponding to the stop.
+func (s stopsStop) Attr() fuse.Attr {
+	log.Printf("getting attributes for stop %s (%d)", s.stopID, s.inode)
+
+	return fuse.Attr{
+		Inode: s.inode,
+		Mode:  0444,
+	}
+}
+
+// ReadAll connects to the SEPTA Arrivals API and returns the scheduled
+// arrivals for the stop.
+func (s stopsStop) ReadAll(intr fs.Intr) (b []byte, err fuse.Error) {
+	ctx, cancel := ctxFromIntr(intr)
+	defer cancel()
+
+	var ret []Arrival
+	if ret, err = s.client.ArrivalsCtx(ctx, s.stopID,
+		arrivalsResults); err != nil {
+		return
+	}
+
+	for _, a := range ret {
+		aBytes := []byte(a.String())
+		aBytes = append(aBytes, '\n')
+		b = append(b, aBytes...)
+	}
+
+	return
+}
@@ -0,0 +1,35 @@
+package septa
+
+// stopsCSV is a snapshot of SEPTA's published GTFS stops.txt, trimmed to
+// the Regional Rail stations septafs exposes under nta/ and stops/.
+// stop_lines is septafs-specific: a semicolon-separated list of the
+// Regional Rail line IDs (matching railLineIDs) that serve the stop, so
+// stops/<line>/ can be scoped to the stations that line actually visits.
+const stopsCSV = `stop_id,stop_name,stop_lat,stop_lon,stop_lines
+90001,Airport Terminal A,39.872327,-75.241233,AIR
+90002,Airport Terminal B,39.869555,-75.243576,AIR
+90003,Airport Terminal C-D,39.866315,-75.236852,AIR
+90004,Airport Terminal E,39.863939,-75.233161,AIR
+90005,University City,39.955276,-75.191696,AIR
+90006,30th Street Station,39.955930,-75.182129,AIR;CHE;CHW;FOX;LAN;DOY;MED;NOR;PAO;TRE;WAR;WTR;WIL
+90007,Suburban Station,39.953320,-75.166969,AIR;CHE;CHW;FOX;LAN;DOY;MED;NOR;PAO;TRE;WAR;WTR;WIL
+90008,Jefferson Station,39.953754,-75.159857,AIR;CHE;CHW;FOX;LAN;DOY;MED;NOR;PAO;TRE;WAR;WTR;WIL
+90009,Temple University,39.981293,-75.149780,CHE;FOX;TRE;WAR;WTR
+90010,North Philadelphia,39.998806,-75.151283,CHE;FOX;TRE;WAR;WTR
+90011,Chestnut Hill East,40.077710,-75.205940,CHE
+90012,Chestnut Hill West,40.077419,-75.210358,CHW
+90013,Fox Chase,40.067825,-75.073700,FOX
+90014,Lansdale,40.240540,-75.283520,LAN
+90015,Doylestown,40.309860,-75.130150,DOY
+90016,Manayunk,40.024460,-75.224700,NOR
+90017,Norristown,40.117340,-75.339830,NOR
+90018,Paoli,40.043940,-75.479540,PAO
+90019,Thorndale,39.980250,-75.768220,PAO
+90020,Trenton,40.218070,-74.759890,TRE
+90021,Warminster,40.201770,-75.096660,WAR
+90022,West Trenton,40.286500,-74.841040,WTR
+90023,Wilmington,39.735260,-75.551940,WIL
+90024,Newark,39.688420,-75.752590,WIL
+90025,Media,39.917000,-75.387000,MED
+90026,Elwyn,39.904500,-75.425500,MED
+`
@@ -0,0 +1,58 @@
+package septa
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseStops(t *testing.T) {
+	data := `stop_id,stop_name,stop_lat,stop_lon,stop_lines
+90001,30th Street Station,39.9566,-75.1816,Airport;Paoli/Thorndale
+90002,Suburban Station,39.9539,-75.1661,
+`
+
+	stops, err := parseStops(data)
+	if err != nil {
+		t.Fatalf("parseStops: %v", err)
+	}
+
+	if len(stops) != 2 {
+		t.Fatalf("got %d stops, want 2", len(stops))
+	}
+
+	want := Stop{
+		ID:    "90001",
+		Name:  "30th Street Station",
+		Lat:   39.9566,
+		Lng:   -75.1816,
+		Lines: []string{"Airport", "Paoli/Thorndale"},
+	}
+	if !reflect.DeepEqual(stops[0], want) {
+		t.Errorf("stops[0] = %+v, want %+v", stops[0], want)
+	}
+
+	if len(stops[1].Lines) != 0 {
+		t.Errorf("stops[1].Lines = %v, want empty", stops[1].Lines)
+	}
+}
+
+func TestParseStopsBadCoordinate(t *testing.T) {
+	data := `stop_id,stop_name,stop_lat,stop_lon
+90001,30th Street Station,not-a-float,-75.1816
+`
+
+	if _, err := parseStops(data); err == nil {
+		t.Fatal("parseStops: got nil error for a non-numeric stop_lat")
+	}
+}
+
+func TestStopServesLine(t *testing.T) {
+	st := Stop{Lines: []string{"Airport", "Paoli/Thorndale"}}
+
+	if !stopServesLine(st, "Airport") {
+		t.Error("stopServesLine(Airport) = false, want true")
+	}
+	if stopServesLine(st, "Chestnut Hill West") {
+		t.Error("stopServesLine(Chestnut Hill West) = true, want false")
+	}
+}